@@ -0,0 +1,289 @@
+// Command smbmarshalgen generates zero-allocation MarshalBytes,
+// UnmarshalBytes, and SizeBytes methods for Go structs tagged with a
+// "+smbmarshal" doc comment, in the spirit of gVisor's "+marshal"
+// generator.
+//
+// A tagged struct may only contain fixed-size fields: unsigned integers
+// (uint8/16/32/64) and fixed-size byte arrays ([N]byte), laid out in
+// declaration order with no implicit padding. Fields of any other type
+// cause the struct to be rejected.
+//
+// Usage:
+//
+//	smbmarshalgen -out response_fixed_generated.go response_fixed.go
+//
+// The generated file implements smbmarshal.Marshalable for every tagged
+// struct in the input file, and additionally emits a Valid method for any
+// struct whose annotation specifies a required value for its
+// StructureSize field, e.g. "+smbmarshal structureSize=65".
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const marker = "+smbmarshal"
+
+var structureSizePattern = regexp.MustCompile(`structureSize=(\d+)`)
+
+// field describes a single fixed-size field of a tagged struct.
+type field struct {
+	Name   string
+	Offset int
+	End    int // Offset + Size, precomputed so the template never emits addition
+	Size   int
+	Array  bool // true if the field is a [N]byte array rather than a uintN
+}
+
+// taggedStruct describes a struct annotated with the +smbmarshal marker.
+type taggedStruct struct {
+	Name             string
+	Fields           []field
+	Size             int
+	StructureSize    int // required value of a field named StructureSize; 0 if unconstrained
+	HasStructureSize bool
+}
+
+func main() {
+	out := flag.String("out", "", "output file path")
+	flag.Parse()
+
+	if *out == "" || flag.NArg() == 0 {
+		log.Fatal("usage: smbmarshalgen -out <file> <input.go>...")
+	}
+
+	fset := token.NewFileSet()
+
+	var pkgName string
+	var structs []taggedStruct
+
+	for _, path := range flag.Args() {
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			log.Fatalf("parsing %s: %v", path, err)
+		}
+		pkgName = f.Name.Name
+
+		found, err := extractTaggedStructs(f)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		structs = append(structs, found...)
+	}
+
+	if len(structs) == 0 {
+		log.Fatalf("no struct tagged %q found in input", marker)
+	}
+
+	src, err := render(pkgName, strings.Join(flag.Args(), ", "), structs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// extractTaggedStructs finds every struct type declaration in f whose doc
+// comment contains the +smbmarshal marker, and computes its field layout.
+func extractTaggedStructs(f *ast.File) ([]taggedStruct, error) {
+	var structs []taggedStruct
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			doc := ts.Doc
+			if doc == nil {
+				doc = gen.Doc
+			}
+			if doc == nil || !strings.Contains(doc.Text(), marker) {
+				continue
+			}
+
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s is tagged %q but is not a struct", f.Name.Name, ts.Name.Name, marker)
+			}
+
+			tagged, err := layout(ts.Name.Name, st)
+			if err != nil {
+				return nil, err
+			}
+
+			if m := structureSizePattern.FindStringSubmatch(doc.Text()); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				tagged.StructureSize = n
+				tagged.HasStructureSize = true
+			}
+
+			structs = append(structs, tagged)
+		}
+	}
+
+	return structs, nil
+}
+
+// layout computes the byte layout of a tagged struct's fields, in
+// declaration order with no padding.
+func layout(name string, st *ast.StructType) (taggedStruct, error) {
+	out := taggedStruct{Name: name}
+	offset := 0
+
+	for _, f := range st.Fields.List {
+		size, isArray, err := fieldSize(f.Type)
+		if err != nil {
+			return out, fmt.Errorf("%s: %v", name, err)
+		}
+		for _, n := range f.Names {
+			out.Fields = append(out.Fields, field{
+				Name:   n.Name,
+				Offset: offset,
+				End:    offset + size,
+				Size:   size,
+				Array:  isArray,
+			})
+			offset += size
+		}
+	}
+
+	out.Size = offset
+	return out, nil
+}
+
+// fieldSize returns the size in bytes of a field type supported by
+// smbmarshalgen: uint8, uint16, uint32, uint64, and [N]byte arrays.
+func fieldSize(expr ast.Expr) (size int, isArray bool, err error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "uint8", "byte":
+			return 1, false, nil
+		case "uint16":
+			return 2, false, nil
+		case "uint32":
+			return 4, false, nil
+		case "uint64":
+			return 8, false, nil
+		default:
+			return 0, false, fmt.Errorf("unsupported field type %q", t.Name)
+		}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return 0, false, fmt.Errorf("slice fields are not supported, only fixed-size arrays")
+		}
+		lit, ok := t.Len.(*ast.BasicLit)
+		if !ok {
+			return 0, false, fmt.Errorf("array length must be a literal")
+		}
+		elem, ok := t.Elt.(*ast.Ident)
+		if !ok || elem.Name != "byte" {
+			return 0, false, fmt.Errorf("only [N]byte arrays are supported")
+		}
+		n, err := strconv.Atoi(lit.Value)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid array length: %v", err)
+		}
+		return n, true, nil
+	default:
+		return 0, false, fmt.Errorf("unsupported field type %T", expr)
+	}
+}
+
+var tmpl = template.Must(template.New("generated").Parse(`// Code generated by cmd/smbmarshalgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+{{range .Structs}}
+// SizeBytes implements smbmarshal.Marshalable.SizeBytes.
+func (v *{{.Name}}) SizeBytes() int {
+	return {{.Size}}
+}
+
+// MarshalBytes implements smbmarshal.Marshalable.MarshalBytes.
+func (v *{{.Name}}) MarshalBytes(dst []byte) {
+{{- range .Fields}}
+{{- if .Array}}
+	copy(dst[{{.Offset}}:{{.End}}], v.{{.Name}}[:])
+{{- else if eq .Size 1}}
+	dst[{{.Offset}}] = v.{{.Name}}
+{{- else if eq .Size 2}}
+	binary.LittleEndian.PutUint16(dst[{{.Offset}}:{{.End}}], v.{{.Name}})
+{{- else if eq .Size 4}}
+	binary.LittleEndian.PutUint32(dst[{{.Offset}}:{{.End}}], v.{{.Name}})
+{{- else if eq .Size 8}}
+	binary.LittleEndian.PutUint64(dst[{{.Offset}}:{{.End}}], v.{{.Name}})
+{{- end}}
+{{- end}}
+}
+
+// UnmarshalBytes implements smbmarshal.Marshalable.UnmarshalBytes. It
+// returns an error, rather than panicking, if src is shorter than
+// SizeBytes().
+func (v *{{.Name}}) UnmarshalBytes(src []byte) error {
+	if len(src) < {{.Size}} {
+		return fmt.Errorf("{{.Name}}.UnmarshalBytes: need {{.Size}} bytes, got %d", len(src))
+	}
+{{- range .Fields}}
+{{- if .Array}}
+	copy(v.{{.Name}}[:], src[{{.Offset}}:{{.End}}])
+{{- else if eq .Size 1}}
+	v.{{.Name}} = src[{{.Offset}}]
+{{- else if eq .Size 2}}
+	v.{{.Name}} = binary.LittleEndian.Uint16(src[{{.Offset}}:{{.End}}])
+{{- else if eq .Size 4}}
+	v.{{.Name}} = binary.LittleEndian.Uint32(src[{{.Offset}}:{{.End}}])
+{{- else if eq .Size 8}}
+	v.{{.Name}} = binary.LittleEndian.Uint64(src[{{.Offset}}:{{.End}}])
+{{- end}}
+{{- end}}
+	return nil
+}
+{{if .HasStructureSize}}
+// Valid reports whether the StructureSize field holds the value required
+// by the specification.
+func (v *{{.Name}}) Valid() bool {
+	return v.StructureSize == {{.StructureSize}}
+}
+{{end}}
+{{- end}}
+`))
+
+type renderData struct {
+	Package string
+	Source  string
+	Structs []taggedStruct
+}
+
+func render(pkg, source string, structs []taggedStruct) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, renderData{Package: pkg, Source: source, Structs: structs}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,75 @@
+// Command smbscan probes one or more hosts with an SMB NEGOTIATE request
+// and prints a JSON record of the negotiated dialect and capabilities for
+// each, one record per line.
+//
+// Usage:
+//
+//	smbscan [-concurrency N] [-timeout D] target [target...]
+//
+// A target is either a single host (optionally host:port, defaulting to
+// port 445) or a CIDR range, e.g. 10.0.0.0/24.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gentlemanautomaton/smb/smbscan"
+)
+
+func main() {
+	concurrency := flag.Int("concurrency", 32, "number of hosts to probe concurrently")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout per host")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] target [target...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	scanner := smbscan.Scanner{
+		Timeout:     *timeout,
+		Concurrency: *concurrency,
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	var mu sync.Mutex
+
+	emit := func(result smbscan.Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := enc.Encode(result); err != nil {
+			log.Printf("smbscan: failed to encode result for %s: %v", result.Address, err)
+		}
+	}
+
+	for _, target := range flag.Args() {
+		if strings.Contains(target, "/") {
+			results, err := scanner.ScanCIDR(target)
+			if err != nil {
+				log.Printf("smbscan: %s: %v", target, err)
+				continue
+			}
+			for result := range results {
+				emit(result)
+			}
+			continue
+		}
+
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			target = net.JoinHostPort(target, "445")
+		}
+		emit(scanner.ScanHost(target))
+	}
+}
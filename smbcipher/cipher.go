@@ -0,0 +1,32 @@
+// Package smbcipher defines the encryption cipher identifiers used in the
+// SMB 3.1.1 encryption capabilities negotiate context.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/16693be7-2b27-4d3b-804b-f605bde5bcdd
+package smbcipher
+
+// Cipher identifies an AEAD cipher supported for SMB3 message encryption.
+type Cipher uint16
+
+// Ciphers defined by the specification.
+const (
+	AES128CCM Cipher = 0x0001
+	AES128GCM Cipher = 0x0002
+	AES256CCM Cipher = 0x0003
+	AES256GCM Cipher = 0x0004
+)
+
+// String returns a human-readable representation of the cipher.
+func (c Cipher) String() string {
+	switch c {
+	case AES128CCM:
+		return "AES-128-CCM"
+	case AES128GCM:
+		return "AES-128-GCM"
+	case AES256CCM:
+		return "AES-256-CCM"
+	case AES256GCM:
+		return "AES-256-GCM"
+	default:
+		return "unknown"
+	}
+}
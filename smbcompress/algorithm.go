@@ -0,0 +1,35 @@
+// Package smbcompress defines the compression algorithm identifiers used in
+// the SMB 3.1.1 compression capabilities negotiate context.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/78e0c942-ab41-472b-b117-6587e1c54a4e
+package smbcompress
+
+// Algorithm identifies a compression algorithm.
+type Algorithm uint16
+
+// Algorithms defined by the specification.
+const (
+	None        Algorithm = 0x0000
+	LZNT1       Algorithm = 0x0001
+	LZ77        Algorithm = 0x0002
+	LZ77Huffman Algorithm = 0x0003
+	PatternV1   Algorithm = 0x0004
+)
+
+// String returns a human-readable representation of the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case None:
+		return "None"
+	case LZNT1:
+		return "LZNT1"
+	case LZ77:
+		return "LZ77"
+	case LZ77Huffman:
+		return "LZ77+Huffman"
+	case PatternV1:
+		return "Pattern_V1"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,208 @@
+package smbcrypt
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// blockSize is the block size required of the cipher passed to newCCM. CCM
+// is only defined for 128-bit block ciphers (in practice, AES).
+const blockSize = 16
+
+// ccmAEAD implements the CCM mode of operation (NIST SP 800-38C, also
+// described in RFC 3610) over a 128-bit block cipher, with an explicit
+// nonce and tag size rather than the more common 12-byte nonce / 16-byte
+// tag combination baked into some CCM implementations.
+//
+// It exists so that smbcrypt does not depend on a third-party CCM package;
+// MS-SMB2 requires an 11-byte nonce that not every implementation exposes.
+type ccmAEAD struct {
+	block     cipher.Block
+	nonceSize int
+	tagSize   int
+}
+
+// newCCM returns a CCM AEAD over block, using the given nonce and tag
+// sizes. tagSize must be even and between 4 and 16 inclusive; nonceSize
+// must leave room for an 8-byte-aligned-or-smaller message length field,
+// i.e. be between 7 and 13 inclusive.
+func newCCM(block cipher.Block, nonceSize, tagSize int) (cipher.AEAD, error) {
+	if block.BlockSize() != blockSize {
+		return nil, errors.New("smbcrypt: CCM requires a 128-bit block cipher")
+	}
+	if nonceSize < 7 || nonceSize > 13 {
+		return nil, errors.New("smbcrypt: invalid CCM nonce size")
+	}
+	if tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		return nil, errors.New("smbcrypt: invalid CCM tag size")
+	}
+	return &ccmAEAD{block: block, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+// NonceSize implements cipher.AEAD.
+func (c *ccmAEAD) NonceSize() int { return c.nonceSize }
+
+// Overhead implements cipher.AEAD.
+func (c *ccmAEAD) Overhead() int { return c.tagSize }
+
+// lengthFieldSize is the size in bytes, q, of the message length field
+// carried in each counter block, as defined by RFC 3610 §2.2: q = 15 - N.
+func (c *ccmAEAD) lengthFieldSize() int {
+	return 15 - c.nonceSize
+}
+
+// Seal implements cipher.AEAD. The dst, nonce, plaintext, and data
+// arguments follow the same conventions as crypto/cipher.AEAD.Seal.
+func (c *ccmAEAD) Seal(dst, nonce, plaintext, data []byte) []byte {
+	if len(nonce) != c.nonceSize {
+		panic("smbcrypt: incorrect nonce length given to CCM")
+	}
+
+	tag := c.mac(nonce, plaintext, data)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+c.tagSize)
+	c.ctr(out[:len(plaintext)], plaintext, nonce, 1)
+	c.ctr(out[len(plaintext):], tag, nonce, 0)
+	return ret
+}
+
+// Open implements cipher.AEAD. The dst, nonce, ciphertext, and data
+// arguments follow the same conventions as crypto/cipher.AEAD.Open.
+func (c *ccmAEAD) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		panic("smbcrypt: incorrect nonce length given to CCM")
+	}
+	if len(ciphertext) < c.tagSize {
+		return nil, errors.New("smbcrypt: CCM ciphertext too short")
+	}
+
+	encTag := ciphertext[len(ciphertext)-c.tagSize:]
+	ct := ciphertext[:len(ciphertext)-c.tagSize]
+
+	ret, out := sliceForAppend(dst, len(ct))
+	c.ctr(out, ct, nonce, 1)
+
+	gotTag := make([]byte, c.tagSize)
+	c.ctr(gotTag, encTag, nonce, 0)
+
+	wantTag := c.mac(nonce, out, data)
+	if subtle.ConstantTimeCompare(gotTag, wantTag) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errors.New("smbcrypt: CCM message authentication failed")
+	}
+
+	return ret, nil
+}
+
+// ctr encrypts or decrypts src into dst using the CTR-mode keystream
+// defined by RFC 3610 §2.3, starting at the given counter value. It is
+// used both to protect the message body (starting at counter 1) and to
+// protect the MAC tag (counter 0).
+func (c *ccmAEAD) ctr(dst, src []byte, nonce []byte, counter uint64) {
+	q := c.lengthFieldSize()
+
+	var a [blockSize]byte
+	a[0] = byte(q - 1)
+	copy(a[1:1+c.nonceSize], nonce)
+
+	var ks [blockSize]byte
+	for len(src) > 0 {
+		putCounter(a[1+c.nonceSize:blockSize], counter)
+		c.block.Encrypt(ks[:], a[:])
+
+		n := len(src)
+		if n > blockSize {
+			n = blockSize
+		}
+		for i := 0; i < n; i++ {
+			dst[i] = src[i] ^ ks[i]
+		}
+		src = src[n:]
+		dst = dst[n:]
+		counter++
+	}
+}
+
+// mac computes the CBC-MAC of plaintext and data under nonce, following
+// RFC 3610 §2.2, and returns the first tagSize bytes of the final block.
+func (c *ccmAEAD) mac(nonce, plaintext, data []byte) []byte {
+	q := c.lengthFieldSize()
+
+	var flags byte
+	flags |= byte((c.tagSize - 2) / 2 << 3)
+	flags |= byte(q - 1)
+	if len(data) > 0 {
+		flags |= 1 << 6
+	}
+
+	var b0 [blockSize]byte
+	b0[0] = flags
+	copy(b0[1:1+c.nonceSize], nonce)
+	putCounter(b0[1+c.nonceSize:blockSize], uint64(len(plaintext)))
+
+	var y [blockSize]byte
+	c.block.Encrypt(y[:], b0[:])
+
+	if len(data) > 0 {
+		// The associated data is prefixed with its length, encoded as a
+		// 2-byte big-endian field; this library only ever authenticates
+		// the short, fixed-size TRANSFORM_HEADER as associated data, well
+		// under the 2^16-2^8 threshold at which RFC 3610 requires a wider
+		// encoding.
+		prefixed := make([]byte, 2+len(data))
+		binary.BigEndian.PutUint16(prefixed, uint16(len(data)))
+		copy(prefixed[2:], data)
+		y = cbcMacBlocks(c.block, y, prefixed)
+	}
+	y = cbcMacBlocks(c.block, y, plaintext)
+
+	return y[:c.tagSize]
+}
+
+// cbcMacBlocks extends the CBC-MAC state y over data, zero-padding the
+// final block if data is not a multiple of the block size.
+func cbcMacBlocks(block cipher.Block, y [blockSize]byte, data []byte) [blockSize]byte {
+	var chunk [blockSize]byte
+	for len(data) > 0 {
+		for i := range chunk {
+			chunk[i] = 0
+		}
+		n := copy(chunk[:], data)
+		data = data[n:]
+
+		for i := 0; i < blockSize; i++ {
+			chunk[i] ^= y[i]
+		}
+		block.Encrypt(y[:], chunk[:])
+	}
+	return y
+}
+
+// putCounter writes v as a big-endian integer into dst, whose length is
+// the counter/length field size q for the CCM instance in use.
+func putCounter(dst []byte, v uint64) {
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = byte(v)
+		v >>= 8
+	}
+}
+
+// sliceForAppend extends in-place, or reallocates, dst by n bytes,
+// mirroring the helper of the same name in crypto/cipher's GCM
+// implementation: head is the full slice to return to the caller, tail is
+// the n newly-available bytes to fill in.
+func sliceForAppend(dst []byte, n int) (head, tail []byte) {
+	total := len(dst) + n
+	if cap(dst) >= total {
+		head = dst[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, dst)
+	}
+	tail = head[len(dst):]
+	return
+}
@@ -0,0 +1,65 @@
+package smbcrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+// TestCCMRoundTripWithAssociatedData confirms that newCCM's Seal/Open round
+// trip plaintext correctly both with and without associated data, and that
+// Open rejects a tag computed under the wrong associated data.
+func TestCCMRoundTripWithAssociatedData(t *testing.T) {
+	key := make([]byte, 16)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher: %v", err)
+	}
+	aead, err := newCCM(block, 11, 16)
+	if err != nil {
+		t.Fatalf("newCCM: %v", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	for i := range nonce {
+		nonce[i] = byte(0x80 + i)
+	}
+	plaintext := []byte("the quick brown fox jumps over the lazy dog")
+	data := []byte("associated data")
+
+	sealed := aead.Seal(nil, nonce, plaintext, data)
+
+	got, err := aead.Open(nil, nonce, sealed, data)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("round trip mismatch: got %q, want %q", got, plaintext)
+	}
+
+	if _, err := aead.Open(nil, nonce, sealed, []byte("wrong associated data")); err == nil {
+		t.Fatalf("expected Open to reject a tag computed under different associated data")
+	}
+}
+
+// TestNewCCMRejectsInvalidSizes confirms that newCCM validates the nonce and
+// tag sizes it is given rather than silently accepting out-of-range values.
+func TestNewCCMRejectsInvalidSizes(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatalf("aes.NewCipher: %v", err)
+	}
+
+	if _, err := newCCM(block, 6, 16); err == nil {
+		t.Fatalf("expected newCCM to reject a nonce size below 7")
+	}
+	if _, err := newCCM(block, 11, 15); err == nil {
+		t.Fatalf("expected newCCM to reject an odd tag size")
+	}
+	if _, err := newCCM(block, 11, 18); err == nil {
+		t.Fatalf("expected newCCM to reject a tag size above 16")
+	}
+}
@@ -0,0 +1,145 @@
+// Package smbcrypt implements the SMB2 TRANSFORM_HEADER encryption layer
+// used to protect a session once SMB 3.1.1 encryption has been negotiated
+// (see the smbnego EncryptionCapabilities context).
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/d6ce2327-a4c9-4433-9c17-18e7fc4eb03e
+package smbcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gentlemanautomaton/smb/smbcipher"
+)
+
+// HeaderLength is the length in bytes of an SMB2 TRANSFORM_HEADER.
+const HeaderLength = 52
+
+// protocolID identifies an SMB2 transform (encrypted) packet, as opposed to
+// the 0xFE 'S' 'M' 'B' ID used by a plaintext SMB2 packet.
+var protocolID = [4]byte{0xFD, 'S', 'M', 'B'}
+
+// Wrap encrypts plaintext under the given cipher and 128- or 256-bit
+// session key, and returns a fully framed SMB2 transform packet: a 52-byte
+// TRANSFORM_HEADER followed by the ciphertext.
+//
+// The associated data authenticated alongside plaintext is the transform
+// header from byte 20 onward (the nonce, original message size, reserved
+// field, encryption algorithm, and session ID), as required by
+// MS-SMB2 §3.1.4.3.
+func Wrap(c smbcipher.Cipher, sessionKey, plaintext []byte, sessionID uint64) ([]byte, error) {
+	aead, err := newAEAD(c, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceLen, err := nonceSize(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:nonceLen]); err != nil {
+		return nil, fmt.Errorf("smbcrypt: generating nonce: %w", err)
+	}
+
+	header := make([]byte, HeaderLength)
+	copy(header[0:4], protocolID[:])
+	copy(header[20:36], nonce[:])
+	binary.LittleEndian.PutUint32(header[36:40], uint32(len(plaintext)))
+	binary.LittleEndian.PutUint16(header[42:44], uint16(c))
+	binary.LittleEndian.PutUint64(header[44:52], sessionID)
+
+	aad := header[20:52]
+	sealed := aead.Seal(nil, nonce[:nonceLen], plaintext, aad)
+
+	tagSize := aead.Overhead()
+	ciphertext, tag := sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+	copy(header[4:20], tag)
+
+	return append(header, ciphertext...), nil
+}
+
+// Unwrap parses an SMB2 transform packet produced by Wrap, decrypting its
+// payload under the given cipher and session key.
+func Unwrap(c smbcipher.Cipher, sessionKey, packet []byte) ([]byte, error) {
+	if len(packet) < HeaderLength {
+		return nil, fmt.Errorf("smbcrypt: packet too short for a transform header (%d bytes)", len(packet))
+	}
+	header, ciphertext := packet[:HeaderLength], packet[HeaderLength:]
+
+	if string(header[0:4]) != string(protocolID[:]) {
+		return nil, fmt.Errorf("smbcrypt: not an SMB2 transform packet")
+	}
+
+	aead, err := newAEAD(c, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceLen, err := nonceSize(c)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := header[20:36][:nonceLen]
+	tag := header[4:20]
+	aad := header[20:52]
+
+	sealed := append(append([]byte(nil), ciphertext...), tag...)
+	plaintext, err := aead.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, fmt.Errorf("smbcrypt: decryption failed: %w", err)
+	}
+
+	if want := binary.LittleEndian.Uint32(header[36:40]); uint32(len(plaintext)) != want {
+		return nil, fmt.Errorf("smbcrypt: decrypted length %d does not match OriginalMessageSize %d", len(plaintext), want)
+	}
+
+	return plaintext, nil
+}
+
+// nonceSize returns the AEAD nonce size used by the given cipher, per
+// MS-SMB2 §3.1.4.3: 11 bytes for the CCM ciphers, 12 bytes for the GCM
+// ciphers. Both are carried zero-padded in the 16-byte Nonce field.
+func nonceSize(c smbcipher.Cipher) (int, error) {
+	switch c {
+	case smbcipher.AES128CCM, smbcipher.AES256CCM:
+		return 11, nil
+	case smbcipher.AES128GCM, smbcipher.AES256GCM:
+		return 12, nil
+	default:
+		return 0, fmt.Errorf("smbcrypt: unsupported cipher %s", c)
+	}
+}
+
+// ccmTagSize is the length in bytes of the authentication tag produced by
+// the CCM ciphers, matching the GCM ciphers' tag size so that both fit the
+// TRANSFORM_HEADER's 16-byte Signature field.
+const ccmTagSize = 16
+
+// newAEAD constructs the AEAD implementation for the given cipher and
+// session key. AES128/AES256 is selected by the length of sessionKey, as
+// required by the respective cipher.
+//
+// The CCM ciphers are constructed with an explicit nonce and tag size, via
+// this package's own CCM implementation (see ccm.go), to pin them to the
+// 11-byte nonce required by MS-SMB2 §3.1.4.3.
+func newAEAD(c smbcipher.Cipher, sessionKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("smbcrypt: %w", err)
+	}
+
+	switch c {
+	case smbcipher.AES128GCM, smbcipher.AES256GCM:
+		return cipher.NewGCM(block)
+	case smbcipher.AES128CCM, smbcipher.AES256CCM:
+		return newCCM(block, 11, ccmTagSize)
+	default:
+		return nil, fmt.Errorf("smbcrypt: unsupported cipher %s", c)
+	}
+}
@@ -0,0 +1,63 @@
+package smbcrypt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gentlemanautomaton/smb/smbcipher"
+)
+
+// TestWrapUnwrapRoundTrip confirms that Unwrap recovers the exact plaintext
+// passed to Wrap, for each cipher negotiated via EncryptionCapabilities.
+func TestWrapUnwrapRoundTrip(t *testing.T) {
+	plaintext := []byte("this is a test SMB2 message payload")
+	const sessionID = 0x0123456789ABCDEF
+
+	cases := []struct {
+		name string
+		c    smbcipher.Cipher
+		key  []byte
+	}{
+		{"AES128CCM", smbcipher.AES128CCM, make([]byte, 16)},
+		{"AES256CCM", smbcipher.AES256CCM, make([]byte, 32)},
+		{"AES128GCM", smbcipher.AES128GCM, make([]byte, 16)},
+		{"AES256GCM", smbcipher.AES256GCM, make([]byte, 32)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := range tc.key {
+				tc.key[i] = byte(i)
+			}
+
+			packet, err := Wrap(tc.c, tc.key, plaintext, sessionID)
+			if err != nil {
+				t.Fatalf("Wrap: %v", err)
+			}
+
+			got, err := Unwrap(tc.c, tc.key, packet)
+			if err != nil {
+				t.Fatalf("Unwrap: %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Fatalf("round trip mismatch: got %q, want %q", got, plaintext)
+			}
+		})
+	}
+}
+
+// TestUnwrapRejectsTamperedPacket confirms that Unwrap rejects a packet
+// whose ciphertext was modified after Wrap produced it.
+func TestUnwrapRejectsTamperedPacket(t *testing.T) {
+	key := make([]byte, 16)
+	packet, err := Wrap(smbcipher.AES128GCM, key, []byte("hello"), 1)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+
+	packet[len(packet)-1] ^= 0xFF
+
+	if _, err := Unwrap(smbcipher.AES128GCM, key, packet); err == nil {
+		t.Fatalf("expected Unwrap to reject a tampered packet")
+	}
+}
@@ -0,0 +1,24 @@
+// Package smbhash defines the preauthentication integrity hash algorithm
+// identifiers used in the SMB 3.1.1 preauthentication integrity capabilities
+// negotiate context.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/5a07bd66-4734-4af8-abcf-694976d12a27
+package smbhash
+
+// Algorithm identifies a preauthentication integrity hash algorithm.
+type Algorithm uint16
+
+// Algorithms defined by the specification.
+const (
+	SHA512 Algorithm = 0x0001
+)
+
+// String returns a human-readable representation of the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case SHA512:
+		return "SHA-512"
+	default:
+		return "unknown"
+	}
+}
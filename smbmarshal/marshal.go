@@ -0,0 +1,27 @@
+// Package smbmarshal defines the interface implemented by types whose
+// serialization is produced by cmd/smbmarshalgen, the code generator that
+// turns a Go struct tagged "+smbmarshal" into zero-allocation wire-format
+// accessors.
+//
+// The approach mirrors gVisor's "+marshal" code generator: the struct
+// itself is the documentation of the wire layout, and the generated code
+// is mechanically checked against it rather than hand audited field by
+// field against MS-SMB2.
+package smbmarshal
+
+// Marshalable is implemented by generated types that can serialize
+// themselves to and from a flat byte representation in place, without
+// additional allocations.
+type Marshalable interface {
+	// SizeBytes returns the number of bytes occupied by the marshaled
+	// representation of the type.
+	SizeBytes() int
+
+	// MarshalBytes serializes the receiver into dst, which must be at
+	// least SizeBytes() long.
+	MarshalBytes(dst []byte)
+
+	// UnmarshalBytes deserializes the receiver from src. It returns an
+	// error, rather than panicking, if src is shorter than SizeBytes().
+	UnmarshalBytes(src []byte) error
+}
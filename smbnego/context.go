@@ -0,0 +1,134 @@
+package smbnego
+
+// ContextHeaderLength is the length in bytes of a negotiate context header,
+// not including its data.
+const ContextHeaderLength = 8
+
+// ContextType identifies the type of a negotiate context.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/15332256-522e-4a53-8cd7-0bd17678a2f7
+type ContextType uint16
+
+// Negotiate context types defined by the specification.
+const (
+	ContextTypePreauthIntegrityCapabilities ContextType = 0x0001
+	ContextTypeEncryptionCapabilities       ContextType = 0x0002
+	ContextTypeCompressionCapabilities      ContextType = 0x0003
+	ContextTypeNetnameNegotiate             ContextType = 0x0005
+	ContextTypeTransportCapabilities        ContextType = 0x0006
+	ContextTypeRDMATransformCapabilities    ContextType = 0x0007
+	ContextTypeSigningCapabilities          ContextType = 0x0008
+)
+
+// String returns a human-readable representation of the context type.
+func (t ContextType) String() string {
+	switch t {
+	case ContextTypePreauthIntegrityCapabilities:
+		return "PreauthIntegrityCapabilities"
+	case ContextTypeEncryptionCapabilities:
+		return "EncryptionCapabilities"
+	case ContextTypeCompressionCapabilities:
+		return "CompressionCapabilities"
+	case ContextTypeNetnameNegotiate:
+		return "NetnameNegotiate"
+	case ContextTypeTransportCapabilities:
+		return "TransportCapabilities"
+	case ContextTypeRDMATransformCapabilities:
+		return "RDMATransformCapabilities"
+	case ContextTypeSigningCapabilities:
+		return "SigningCapabilities"
+	default:
+		return "unknown"
+	}
+}
+
+// Context interprets a slice of bytes as a single SMB2 negotiate context.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/15332256-522e-4a53-8cd7-0bd17678a2f7
+type Context []byte
+
+// header unmarshals the fixed-size header of the context. The slice is
+// always exactly ContextHeaderLength bytes, so the error return of
+// UnmarshalBytes can never trigger here.
+func (c Context) header() (h ContextHeader) {
+	_ = h.UnmarshalBytes(c[:ContextHeaderLength])
+	return
+}
+
+// putHeader marshals h back into the fixed-size header of the context.
+func (c Context) putHeader(h ContextHeader) {
+	h.MarshalBytes(c[:ContextHeaderLength])
+}
+
+// Type returns the type of the context.
+func (c Context) Type() ContextType {
+	return ContextType(c.header().ContextType)
+}
+
+// SetType sets the type of the context.
+func (c Context) SetType(t ContextType) {
+	h := c.header()
+	h.ContextType = uint16(t)
+	c.putHeader(h)
+}
+
+// DataLength returns the length of the context data, not including the
+// context header or any trailing padding.
+func (c Context) DataLength() uint16 {
+	return c.header().DataLength
+}
+
+// SetDataLength sets the length of the context data.
+func (c Context) SetDataLength(length uint16) {
+	h := c.header()
+	h.DataLength = length
+	c.putHeader(h)
+}
+
+// Data returns the context data, not including the context header or any
+// trailing padding.
+func (c Context) Data() []byte {
+	n := uint(ContextHeaderLength) + uint(c.DataLength())
+	return c[ContextHeaderLength:n:n]
+}
+
+// aligned returns the total length of the context, including its header,
+// data, and any trailing padding needed to reach the next 8-byte boundary.
+func (c Context) aligned() uint {
+	return align8(uint(ContextHeaderLength) + uint(c.DataLength()))
+}
+
+// align8 rounds n up to the next multiple of 8.
+func align8(n uint) uint {
+	if rem := n % 8; rem != 0 {
+		n += 8 - rem
+	}
+	return n
+}
+
+// ContextList is a sequence of negotiate contexts, each starting on an
+// 8-byte boundary as required by MS-SMB2 §2.2.4. The last context need not
+// be followed by padding.
+type ContextList []byte
+
+// Valid returns true if the list contains count contexts, none of which
+// overflow the list.
+func (list ContextList) Valid(count uint16) bool {
+	data := list
+	for i := uint16(0); i < count; i++ {
+		if uint(len(data)) < ContextHeaderLength {
+			return false
+		}
+		ctx := Context(data)
+		if uint(len(data)) < uint(ContextHeaderLength)+uint(ctx.DataLength()) {
+			return false
+		}
+		// The last context need not be followed by padding.
+		need := ctx.aligned()
+		if need > uint(len(data)) {
+			need = uint(len(data))
+		}
+		data = data[need:]
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package smbnego
+
+import (
+	"encoding/binary"
+
+	"github.com/gentlemanautomaton/smb/smbcompress"
+)
+
+// CompressionFlags describes the flags of a compression capabilities
+// context.
+type CompressionFlags uint32
+
+// Compression flags defined by the specification.
+const (
+	CompressionFlagNone    CompressionFlags = 0x00000000
+	CompressionFlagChained CompressionFlags = 0x00000001
+)
+
+// CompressionContext interprets negotiate context data as compression
+// capabilities.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/78e0c942-ab41-472b-b117-6587e1c54a4e
+type CompressionContext Context
+
+// Valid returns true if the context's data is long enough to hold its
+// fixed fields plus the algorithm list they describe.
+func (c CompressionContext) Valid() bool {
+	data := Context(c).Data()
+	if len(data) < 8 {
+		return false
+	}
+	count := int(binary.LittleEndian.Uint16(data[0:2]))
+	return len(data) >= 8+count*2
+}
+
+// AlgorithmCount returns the number of compression algorithms in the
+// context.
+func (c CompressionContext) AlgorithmCount() uint16 {
+	return binary.LittleEndian.Uint16(Context(c).Data()[0:2])
+}
+
+// Flags returns the flags of the context.
+func (c CompressionContext) Flags() CompressionFlags {
+	return CompressionFlags(binary.LittleEndian.Uint32(Context(c).Data()[4:8]))
+}
+
+// Algorithms returns the compression algorithms advertised by the context,
+// in order of preference.
+func (c CompressionContext) Algorithms() []smbcompress.Algorithm {
+	count := c.AlgorithmCount()
+	data := Context(c).Data()[8:]
+	algorithms := make([]smbcompress.Algorithm, count)
+	for i := range algorithms {
+		algorithms[i] = smbcompress.Algorithm(binary.LittleEndian.Uint16(data[i*2 : i*2+2]))
+	}
+	return algorithms
+}
+
+// CompressionCapabilities returns the compression capabilities context from
+// the response, if present.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) CompressionCapabilities() (ctx CompressionContext, ok bool) {
+	r.EachContext(func(c Context) bool {
+		if c.Type() == ContextTypeCompressionCapabilities {
+			candidate := CompressionContext(c)
+			if candidate.Valid() {
+				ctx, ok = candidate, true
+			}
+			return false
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,27 @@
+package smbnego
+
+// EachContext invokes fn once for each negotiate context in the response,
+// in order. It stops early if fn returns false.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) EachContext(fn func(Context) bool) {
+	data := ContextList(r.ContextList())
+	for i := uint16(0); i < r.ContextCount(); i++ {
+		if uint(len(data)) < ContextHeaderLength {
+			return
+		}
+		ctx := Context(data)
+		if uint(len(data)) < uint(ContextHeaderLength)+uint(ctx.DataLength()) {
+			return
+		}
+		// The last context need not be followed by padding.
+		need := ctx.aligned()
+		if need > uint(len(data)) {
+			need = uint(len(data))
+		}
+		if !fn(ctx) {
+			return
+		}
+		data = data[need:]
+	}
+}
@@ -0,0 +1,59 @@
+package smbnego
+
+import (
+	"encoding/binary"
+
+	"github.com/gentlemanautomaton/smb/smbcipher"
+)
+
+// EncryptionContext interprets negotiate context data as encryption
+// capabilities.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/16693be7-2b27-4d3b-804b-f605bde5bcdd
+type EncryptionContext Context
+
+// Valid returns true if the context's data is long enough to hold its
+// fixed fields plus the cipher list they describe.
+func (c EncryptionContext) Valid() bool {
+	data := Context(c).Data()
+	if len(data) < 2 {
+		return false
+	}
+	count := int(binary.LittleEndian.Uint16(data[0:2]))
+	return len(data) >= 2+count*2
+}
+
+// CipherCount returns the number of ciphers in the context.
+func (c EncryptionContext) CipherCount() uint16 {
+	return binary.LittleEndian.Uint16(Context(c).Data()[0:2])
+}
+
+// Ciphers returns the ciphers advertised by the context, in order of
+// preference.
+func (c EncryptionContext) Ciphers() []smbcipher.Cipher {
+	count := c.CipherCount()
+	data := Context(c).Data()[2:]
+	ciphers := make([]smbcipher.Cipher, count)
+	for i := range ciphers {
+		ciphers[i] = smbcipher.Cipher(binary.LittleEndian.Uint16(data[i*2 : i*2+2]))
+	}
+	return ciphers
+}
+
+// EncryptionCapabilities returns the encryption capabilities context from
+// the response, if present.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) EncryptionCapabilities() (ctx EncryptionContext, ok bool) {
+	r.EachContext(func(c Context) bool {
+		if c.Type() == ContextTypeEncryptionCapabilities {
+			candidate := EncryptionContext(c)
+			if candidate.Valid() {
+				ctx, ok = candidate, true
+			}
+			return false
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,17 @@
+package smbnego
+
+//go:generate go run ../cmd/smbmarshalgen -out context_header_generated.go context_header.go
+
+// +smbmarshal
+//
+// ContextHeader describes the 8-byte header shared by every negotiate
+// context, as consumed by cmd/smbmarshalgen to generate its
+// MarshalBytes, UnmarshalBytes, and SizeBytes implementation in
+// context_header_generated.go.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/15332256-522e-4a53-8cd7-0bd17678a2f7
+type ContextHeader struct {
+	ContextType uint16
+	DataLength  uint16
+	Reserved    uint32
+}
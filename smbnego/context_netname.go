@@ -0,0 +1,35 @@
+package smbnego
+
+import "unicode/utf16"
+
+// NetnameContext interprets negotiate context data as a netname negotiate
+// context, used by clients to indicate the server name they connected to.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/2fa1a2d0-02f5-44d9-b9a2-e96a12a6de72
+type NetnameContext Context
+
+// NetName returns the net name carried by the context, decoded from
+// UTF-16LE.
+func (c NetnameContext) NetName() string {
+	data := Context(c).Data()
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = uint16(data[i*2]) | uint16(data[i*2+1])<<8
+	}
+	return string(utf16.Decode(units))
+}
+
+// NetnameNegotiateContextID returns the netname negotiate context from the
+// response, if present.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) NetnameNegotiateContextID() (ctx NetnameContext, ok bool) {
+	r.EachContext(func(c Context) bool {
+		if c.Type() == ContextTypeNetnameNegotiate {
+			ctx, ok = NetnameContext(c), true
+			return false
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,73 @@
+package smbnego
+
+import (
+	"encoding/binary"
+
+	"github.com/gentlemanautomaton/smb/smbhash"
+)
+
+// PreauthIntegrityContext interprets negotiate context data as
+// preauthentication integrity capabilities.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/5a07bd66-4734-4af8-abcf-694976d12a27
+type PreauthIntegrityContext Context
+
+// Valid returns true if the context's data is long enough to hold its
+// fixed fields plus the hash algorithm list and salt they describe.
+func (c PreauthIntegrityContext) Valid() bool {
+	data := Context(c).Data()
+	if len(data) < 4 {
+		return false
+	}
+	count := int(binary.LittleEndian.Uint16(data[0:2]))
+	saltLength := int(binary.LittleEndian.Uint16(data[2:4]))
+	return len(data) >= 4+count*2+saltLength
+}
+
+// HashAlgorithmCount returns the number of hash algorithms in the context.
+func (c PreauthIntegrityContext) HashAlgorithmCount() uint16 {
+	return binary.LittleEndian.Uint16(Context(c).Data()[0:2])
+}
+
+// SaltLength returns the length of the salt in the context.
+func (c PreauthIntegrityContext) SaltLength() uint16 {
+	return binary.LittleEndian.Uint16(Context(c).Data()[2:4])
+}
+
+// HashAlgorithms returns the hash algorithms advertised by the context, in
+// order of preference.
+func (c PreauthIntegrityContext) HashAlgorithms() []smbhash.Algorithm {
+	count := c.HashAlgorithmCount()
+	data := Context(c).Data()[4:]
+	algorithms := make([]smbhash.Algorithm, count)
+	for i := range algorithms {
+		algorithms[i] = smbhash.Algorithm(binary.LittleEndian.Uint16(data[i*2 : i*2+2]))
+	}
+	return algorithms
+}
+
+// Salt returns the salt value of the context.
+func (c PreauthIntegrityContext) Salt() []byte {
+	offset := 4 + int(c.HashAlgorithmCount())*2
+	length := int(c.SaltLength())
+	data := Context(c).Data()[offset:]
+	return data[:length:length]
+}
+
+// PreauthIntegrityCapabilities returns the preauthentication integrity
+// capabilities context from the response, if present.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) PreauthIntegrityCapabilities() (ctx PreauthIntegrityContext, ok bool) {
+	r.EachContext(func(c Context) bool {
+		if c.Type() == ContextTypePreauthIntegrityCapabilities {
+			candidate := PreauthIntegrityContext(c)
+			if candidate.Valid() {
+				ctx, ok = candidate, true
+			}
+			return false
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,65 @@
+package smbnego
+
+import "encoding/binary"
+
+// RDMATransform identifies an RDMA transform.
+type RDMATransform uint16
+
+// RDMA transforms defined by the specification.
+const (
+	RDMATransformNone       RDMATransform = 0x0000
+	RDMATransformEncryption RDMATransform = 0x0001
+	RDMATransformSigning    RDMATransform = 0x0002
+)
+
+// RDMATransformContext interprets negotiate context data as RDMA transform
+// capabilities.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/af1d6a0e-0fee-4a7a-8696-a3cc5f5b49f2
+type RDMATransformContext Context
+
+// Valid returns true if the context's data is long enough to hold its
+// fixed fields plus the transform list they describe.
+func (c RDMATransformContext) Valid() bool {
+	data := Context(c).Data()
+	if len(data) < 8 {
+		return false
+	}
+	count := int(binary.LittleEndian.Uint16(data[0:2]))
+	return len(data) >= 8+count*2
+}
+
+// TransformCount returns the number of RDMA transforms in the context.
+func (c RDMATransformContext) TransformCount() uint16 {
+	return binary.LittleEndian.Uint16(Context(c).Data()[0:2])
+}
+
+// Transforms returns the RDMA transforms advertised by the context, in
+// order of preference.
+func (c RDMATransformContext) Transforms() []RDMATransform {
+	count := c.TransformCount()
+	data := Context(c).Data()[8:]
+	transforms := make([]RDMATransform, count)
+	for i := range transforms {
+		transforms[i] = RDMATransform(binary.LittleEndian.Uint16(data[i*2 : i*2+2]))
+	}
+	return transforms
+}
+
+// RDMATransformCapabilities returns the RDMA transform capabilities context
+// from the response, if present.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) RDMATransformCapabilities() (ctx RDMATransformContext, ok bool) {
+	r.EachContext(func(c Context) bool {
+		if c.Type() == ContextTypeRDMATransformCapabilities {
+			candidate := RDMATransformContext(c)
+			if candidate.Valid() {
+				ctx, ok = candidate, true
+			}
+			return false
+		}
+		return true
+	})
+	return
+}
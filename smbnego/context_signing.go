@@ -0,0 +1,64 @@
+package smbnego
+
+import "encoding/binary"
+
+// SigningAlgorithm identifies a packet signing algorithm.
+type SigningAlgorithm uint16
+
+// Signing algorithms defined by the specification.
+const (
+	SigningAlgorithmHMACSHA256 SigningAlgorithm = 0x0000
+	SigningAlgorithmAESCMAC    SigningAlgorithm = 0x0001
+	SigningAlgorithmAESGMAC    SigningAlgorithm = 0x0002
+)
+
+// SigningContext interprets negotiate context data as signing capabilities.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/b9d8208e-ddea-419a-dad7-9d6bf363e0eb
+type SigningContext Context
+
+// Valid returns true if the context's data is long enough to hold its
+// fixed fields plus the algorithm list they describe.
+func (c SigningContext) Valid() bool {
+	data := Context(c).Data()
+	if len(data) < 2 {
+		return false
+	}
+	count := int(binary.LittleEndian.Uint16(data[0:2]))
+	return len(data) >= 2+count*2
+}
+
+// AlgorithmCount returns the number of signing algorithms in the context.
+func (c SigningContext) AlgorithmCount() uint16 {
+	return binary.LittleEndian.Uint16(Context(c).Data()[0:2])
+}
+
+// Algorithms returns the signing algorithms advertised by the context, in
+// order of preference.
+func (c SigningContext) Algorithms() []SigningAlgorithm {
+	count := c.AlgorithmCount()
+	data := Context(c).Data()[2:]
+	algorithms := make([]SigningAlgorithm, count)
+	for i := range algorithms {
+		algorithms[i] = SigningAlgorithm(binary.LittleEndian.Uint16(data[i*2 : i*2+2]))
+	}
+	return algorithms
+}
+
+// SigningCapabilities returns the signing capabilities context from the
+// response, if present.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) SigningCapabilities() (ctx SigningContext, ok bool) {
+	r.EachContext(func(c Context) bool {
+		if c.Type() == ContextTypeSigningCapabilities {
+			candidate := SigningContext(c)
+			if candidate.Valid() {
+				ctx, ok = candidate, true
+			}
+			return false
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,111 @@
+package smbnego
+
+import (
+	"testing"
+
+	"github.com/gentlemanautomaton/smb/smbdialect"
+	"github.com/gentlemanautomaton/smb/smbhash"
+)
+
+// TestContextRoundTrip builds a response with a well-formed preauthentication
+// integrity context via ContextListBuilder and verifies it comes back out
+// through EachContext and the typed accessor unchanged.
+func TestContextRoundTrip(t *testing.T) {
+	preauthData := []byte{
+		0x01, 0x00, // HashAlgorithmCount = 1
+		0x04, 0x00, // SaltLength = 4
+		0x01, 0x00, // HashAlgorithms[0] = SHA-512
+		0xAA, 0xBB, 0xCC, 0xDD, // Salt
+	}
+
+	var b ResponseBuilder
+	b.SetDialectRevision(smbdialect.SMB311)
+	b.AddContext(ContextTypePreauthIntegrityCapabilities, preauthData)
+	r := b.Build()
+
+	if !r.Valid() {
+		t.Fatalf("built response is not valid")
+	}
+
+	ctx, ok := r.PreauthIntegrityCapabilities()
+	if !ok {
+		t.Fatalf("expected a preauthentication integrity context")
+	}
+	if !ctx.Valid() {
+		t.Fatalf("expected the context to be valid")
+	}
+
+	algorithms := ctx.HashAlgorithms()
+	if len(algorithms) != 1 || algorithms[0] != smbhash.SHA512 {
+		t.Fatalf("unexpected hash algorithms: %v", algorithms)
+	}
+
+	salt := ctx.Salt()
+	want := []byte{0xAA, 0xBB, 0xCC, 0xDD}
+	if len(salt) != len(want) {
+		t.Fatalf("unexpected salt length: got %d, want %d", len(salt), len(want))
+	}
+	for i := range want {
+		if salt[i] != want[i] {
+			t.Fatalf("unexpected salt: got %x, want %x", salt, want)
+		}
+	}
+}
+
+// TestTypedContextTruncatedDataDoesNotPanic regresses a bug where a
+// negotiate context whose type matched but whose DataLength was too short
+// for the type's fixed fields caused a typed accessor to panic while
+// slicing Data() at a fixed offset. Such a context is exactly what an
+// arbitrary or hostile peer can send to smbscan.
+func TestTypedContextTruncatedDataDoesNotPanic(t *testing.T) {
+	var b ResponseBuilder
+	b.SetDialectRevision(smbdialect.SMB311)
+	b.AddContext(ContextTypeEncryptionCapabilities, []byte{0x00}) // too short for CipherCount
+	b.AddContext(ContextTypeCompressionCapabilities, []byte{0x00, 0x00, 0x00}) // too short for flags
+	b.AddContext(ContextTypeTransportCapabilities, []byte{0x00, 0x00})         // too short for flags
+	b.AddContext(ContextTypeRDMATransformCapabilities, []byte{0x01, 0x00})     // too short for the header
+	b.AddContext(ContextTypeSigningCapabilities, []byte{})                    // too short for AlgorithmCount
+	r := b.Build()
+
+	if !r.Valid() {
+		t.Fatalf("built response is not valid")
+	}
+
+	if _, ok := r.EncryptionCapabilities(); ok {
+		t.Errorf("expected EncryptionCapabilities to reject truncated data")
+	}
+	if _, ok := r.CompressionCapabilities(); ok {
+		t.Errorf("expected CompressionCapabilities to reject truncated data")
+	}
+	if _, ok := r.TransportCapabilities(); ok {
+		t.Errorf("expected TransportCapabilities to reject truncated data")
+	}
+	if _, ok := r.RDMATransformCapabilities(); ok {
+		t.Errorf("expected RDMATransformCapabilities to reject truncated data")
+	}
+	if _, ok := r.SigningCapabilities(); ok {
+		t.Errorf("expected SigningCapabilities to reject truncated data")
+	}
+}
+
+// TestRequestContextOffsetUnderflowDoesNotPanic regresses a bug where a
+// 3.1.1-dialect request whose ContextOffset was left at (or set to) a value
+// smaller than PacketHeaderLength passed Request.Valid() and then panicked
+// with a slice-bounds error the moment EachContext/ContextList subtracted
+// PacketHeaderLength from it, underflowing the offset. Such a request is
+// exactly what an uninitialized client, or a hostile one, can send to a
+// server parsing untrusted input.
+func TestRequestContextOffsetUnderflowDoesNotPanic(t *testing.T) {
+	var b RequestBuilder
+	b.AddDialect(smbdialect.SMB311)
+	r := b.Build()
+
+	// Simulate a malformed request: 3.1.1 is offered, so ContextOffset and
+	// ContextCount are meaningful, but ContextOffset was left at zero.
+	r.SetContextOffset(0)
+	r.SetContextCount(1)
+
+	if r.Valid() {
+		t.Fatalf("expected a request with ContextOffset < PacketHeaderLength to be invalid")
+	}
+}
@@ -0,0 +1,47 @@
+package smbnego
+
+import "encoding/binary"
+
+// TransportFlags describes the flags of a transport capabilities context.
+type TransportFlags uint32
+
+// Transport flags defined by the specification.
+const (
+	TransportFlagNone                         TransportFlags = 0x00000000
+	TransportFlagAcceptTransportLayerSecurity TransportFlags = 0x00000001
+)
+
+// TransportContext interprets negotiate context data as transport
+// capabilities.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/e465c225-8c88-4df1-9f09-0e36b8f1df1e
+type TransportContext Context
+
+// Valid returns true if the context's data is long enough to hold its
+// fixed flags field.
+func (c TransportContext) Valid() bool {
+	return len(Context(c).Data()) >= 4
+}
+
+// Flags returns the flags of the context.
+func (c TransportContext) Flags() TransportFlags {
+	return TransportFlags(binary.LittleEndian.Uint32(Context(c).Data()[0:4]))
+}
+
+// TransportCapabilities returns the transport capabilities context from the
+// response, if present.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Response) TransportCapabilities() (ctx TransportContext, ok bool) {
+	r.EachContext(func(c Context) bool {
+		if c.Type() == ContextTypeTransportCapabilities {
+			candidate := TransportContext(c)
+			if candidate.Valid() {
+				ctx, ok = candidate, true
+			}
+			return false
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,39 @@
+package smbnego
+
+import "encoding/binary"
+
+// ContextListBuilder assembles a sequence of negotiate contexts into a
+// properly padded and aligned byte buffer, suitable for use as the context
+// list of a Response or Request.
+//
+// The zero value is an empty builder ready to use.
+type ContextListBuilder struct {
+	buf   []byte
+	count uint16
+}
+
+// Add appends a negotiate context of the given type carrying data, padding
+// the preceding context to an 8-byte boundary as required by MS-SMB2 §2.2.4.
+func (b *ContextListBuilder) Add(t ContextType, data []byte) {
+	if rem := len(b.buf) % 8; rem != 0 {
+		b.buf = append(b.buf, make([]byte, 8-rem)...)
+	}
+
+	header := make([]byte, ContextHeaderLength)
+	binary.LittleEndian.PutUint16(header[0:2], uint16(t))
+	binary.LittleEndian.PutUint16(header[2:4], uint16(len(data)))
+
+	b.buf = append(b.buf, header...)
+	b.buf = append(b.buf, data...)
+	b.count++
+}
+
+// Count returns the number of contexts added to the builder so far.
+func (b *ContextListBuilder) Count() uint16 {
+	return b.count
+}
+
+// Bytes returns the assembled, padded context list.
+func (b *ContextListBuilder) Bytes() []byte {
+	return b.buf
+}
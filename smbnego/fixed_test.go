@@ -0,0 +1,69 @@
+package smbnego
+
+import "testing"
+
+// TestFixedStructsRoundTrip exercises MarshalBytes/UnmarshalBytes for every
+// generated fixed-size struct, confirming that unmarshaling a buffer
+// produced by marshaling reproduces the original values.
+func TestFixedStructsRoundTrip(t *testing.T) {
+	rf := RequestFixed{
+		StructureSize: 36,
+		DialectCount:  2,
+		SecurityMode:  1,
+		Capabilities:  0x0F,
+	}
+	buf := make([]byte, rf.SizeBytes())
+	rf.MarshalBytes(buf)
+	var rf2 RequestFixed
+	if err := rf2.UnmarshalBytes(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rf2 != rf {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", rf2, rf)
+	}
+
+	resf := ResponseFixed{
+		StructureSize:   65,
+		DialectRevision: 0x0311,
+		ContextCount:    1,
+	}
+	buf = make([]byte, resf.SizeBytes())
+	resf.MarshalBytes(buf)
+	var resf2 ResponseFixed
+	if err := resf2.UnmarshalBytes(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resf2 != resf {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", resf2, resf)
+	}
+
+	ch := ContextHeader{ContextType: 1, DataLength: 4}
+	buf = make([]byte, ch.SizeBytes())
+	ch.MarshalBytes(buf)
+	var ch2 ContextHeader
+	if err := ch2.UnmarshalBytes(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ch2 != ch {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", ch2, ch)
+	}
+}
+
+// TestFixedStructsUnmarshalTruncated confirms that UnmarshalBytes returns an
+// error, rather than panicking, when src is shorter than SizeBytes().
+func TestFixedStructsUnmarshalTruncated(t *testing.T) {
+	var rf RequestFixed
+	if err := rf.UnmarshalBytes(make([]byte, rf.SizeBytes()-1)); err == nil {
+		t.Errorf("expected an error unmarshaling a truncated RequestFixed")
+	}
+
+	var resf ResponseFixed
+	if err := resf.UnmarshalBytes(make([]byte, resf.SizeBytes()-1)); err == nil {
+		t.Errorf("expected an error unmarshaling a truncated ResponseFixed")
+	}
+
+	var ch ContextHeader
+	if err := ch.UnmarshalBytes(make([]byte, ch.SizeBytes()-1)); err == nil {
+		t.Errorf("expected an error unmarshaling a truncated ContextHeader")
+	}
+}
@@ -0,0 +1,14 @@
+package smbnego
+
+// PacketHeaderLength is the length in bytes of the generic SMB2 packet
+// header (MS-SMB2 §2.2.1) that precedes a negotiate request or response on
+// the wire.
+//
+// SecurityBufferOffset and NegotiateContextOffset are specified relative to
+// the start of that header, even though Request and Response represent only
+// the negotiate-specific body that follows it. Accessors that turn those
+// fields into slices of Request/Response, and builders that compute them,
+// must add or subtract PacketHeaderLength to translate between the two.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/fb188936-5050-48d3-b350-dc43059638a4
+const PacketHeaderLength = 64
@@ -0,0 +1,251 @@
+package smbnego
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/gentlemanautomaton/smb/smbcap"
+	"github.com/gentlemanautomaton/smb/smbdialect"
+	"github.com/gentlemanautomaton/smb/smbid"
+	"github.com/gentlemanautomaton/smb/smbsecmode"
+	"github.com/gentlemanautomaton/smb/smbtype"
+)
+
+// Request interprets a slice of bytes as an SMB negotiation request packet.
+//
+// The fixed-size fields of the request are described by RequestFixed; the
+// accessors below are a thin compatibility layer over its generated
+// MarshalBytes/UnmarshalBytes methods, retained so that callers can keep
+// treating a request as a plain byte slice.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/69a29f73-de0c-45a6-a1aa-8ceeea42217f
+type Request []byte
+
+// fixed unmarshals the fixed-size portion of the request. The slice is
+// always exactly requestHeaderLength bytes, so the error return of
+// UnmarshalBytes can never trigger here.
+func (r Request) fixed() (f RequestFixed) {
+	_ = f.UnmarshalBytes(r[:requestHeaderLength])
+	return
+}
+
+// putFixed marshals f back into the fixed-size portion of the request.
+func (r Request) putFixed(f RequestFixed) {
+	f.MarshalBytes(r[:requestHeaderLength])
+}
+
+// Valid returns true if the request is valid.
+func (r Request) Valid() bool {
+	if len(r) < requestHeaderLength {
+		return false
+	}
+
+	f := r.fixed()
+	if !f.Valid() {
+		return false
+	}
+
+	if int(requestHeaderLength)+int(r.DialectCount())*2 > len(r) {
+		return false
+	}
+
+	// In SMB 3.1.1 the negotiation contexts must not overflow
+	if r.offers311() {
+		// ContextOffset is relative to the SMB2 packet header, which
+		// precedes r; reject it outright rather than let a value smaller
+		// than PacketHeaderLength underflow the subtraction below.
+		ctxOffset := int64(r.ContextOffset()) - PacketHeaderLength
+		if ctxOffset < 0 {
+			return false
+		}
+
+		// Make sure the context count is compatible with the size of the
+		// request. The size of each context is variable but at least 8 bytes.
+		minimumLength := uint64(ctxOffset) + uint64(r.ContextCount())*ContextHeaderLength
+		if minimumLength > uint64(len(r)) {
+			return false
+		}
+
+		// Rely on the context list implementation to determine its own validity
+		if !r.ContextList().Valid(r.ContextCount()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// offers311 returns true if the dialect list includes SMB 3.1.1.
+func (r Request) offers311() bool {
+	for _, d := range r.Dialects() {
+		if d == smbdialect.SMB311 {
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the structure size of the request. The specification
+// requires that this be 36.
+func (r Request) Size() uint16 {
+	return r.fixed().StructureSize
+}
+
+// SetSize sets the structure size of the request.
+func (r Request) SetSize(size uint16) {
+	f := r.fixed()
+	f.StructureSize = size
+	r.putFixed(f)
+}
+
+// DialectCount returns the number of dialects carried by the request.
+func (r Request) DialectCount() uint16 {
+	return r.fixed().DialectCount
+}
+
+// SetDialectCount sets the number of dialects carried by the request.
+func (r Request) SetDialectCount(count uint16) {
+	f := r.fixed()
+	f.DialectCount = count
+	r.putFixed(f)
+}
+
+// SecurityMode returns the security mode of the request.
+func (r Request) SecurityMode() smbsecmode.Flags {
+	return smbsecmode.Flags(r.fixed().SecurityMode)
+}
+
+// SetSecurityMode sets the security mode of the request.
+func (r Request) SetSecurityMode(flags smbsecmode.Flags) {
+	f := r.fixed()
+	f.SecurityMode = uint16(flags)
+	r.putFixed(f)
+}
+
+// Capabilities returns the capability flags of the request.
+func (r Request) Capabilities() smbcap.Flags {
+	return smbcap.Flags(r.fixed().Capabilities)
+}
+
+// SetCapabilities sets the capability flags of the request.
+func (r Request) SetCapabilities(flags smbcap.Flags) {
+	f := r.fixed()
+	f.Capabilities = uint32(flags)
+	r.putFixed(f)
+}
+
+// ClientID returns the client identifier of the request.
+func (r Request) ClientID() (id smbid.ID) {
+	f := r.fixed()
+	id.Read(f.ClientID[:])
+	return
+}
+
+// SetClientID sets the client identifier of the request.
+func (r Request) SetClientID(id smbid.ID) {
+	f := r.fixed()
+	id.Write(f.ClientID[:])
+	r.putFixed(f)
+}
+
+// ClientStartTime returns the client start time of the request.
+//
+// This field is not valid in the SMB 3.1.1 dialect, where the same bytes
+// instead carry the negotiate context offset and count.
+func (r Request) ClientStartTime() time.Time {
+	f := r.fixed()
+	return smbtype.Time(f.Field5[:])
+}
+
+// SetClientStartTime sets the client start time of the request.
+func (r Request) SetClientStartTime(t time.Time) {
+	f := r.fixed()
+	smbtype.PutTime(f.Field5[:], t)
+	r.putFixed(f)
+}
+
+// ContextOffset returns the offset of the first negotiate context,
+// measured from the start of the SMB2 packet header that precedes the
+// request.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Request) ContextOffset() uint32 {
+	f := r.fixed()
+	return binary.LittleEndian.Uint32(f.Field5[0:4])
+}
+
+// SetContextOffset sets the offset of the first negotiate context,
+// measured from the start of the SMB2 packet header that precedes the
+// request.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Request) SetContextOffset(offset uint32) {
+	f := r.fixed()
+	binary.LittleEndian.PutUint32(f.Field5[0:4], offset)
+	r.putFixed(f)
+}
+
+// ContextCount returns the number of negotiate contexts carried by the
+// request.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Request) ContextCount() uint16 {
+	f := r.fixed()
+	return binary.LittleEndian.Uint16(f.Field5[4:6])
+}
+
+// SetContextCount sets the number of negotiate contexts carried by the
+// request.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Request) SetContextCount(count uint16) {
+	f := r.fixed()
+	binary.LittleEndian.PutUint16(f.Field5[4:6], count)
+	r.putFixed(f)
+}
+
+// Dialects returns the dialects offered by the request.
+func (r Request) Dialects() []smbdialect.Revision {
+	count := r.DialectCount()
+	dialects := make([]smbdialect.Revision, count)
+	for i := range dialects {
+		offset := requestHeaderLength + i*2
+		dialects[i] = smbdialect.Revision(binary.LittleEndian.Uint16(r[offset : offset+2]))
+	}
+	return dialects
+}
+
+// ContextList returns the negotiation context list from the request.
+//
+// If r is valid the returned list is guaranteed to be valid.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Request) ContextList() ContextList {
+	return ContextList(r[uint(r.ContextOffset())-PacketHeaderLength:])
+}
+
+// EachContext invokes fn once for each negotiate context in the request, in
+// order. It stops early if fn returns false.
+//
+// This field is only valid in the SMB 3.1.1 dialect.
+func (r Request) EachContext(fn func(Context) bool) {
+	data := r.ContextList()
+	for i := uint16(0); i < r.ContextCount(); i++ {
+		if uint(len(data)) < ContextHeaderLength {
+			return
+		}
+		ctx := Context(data)
+		if uint(len(data)) < uint(ContextHeaderLength)+uint(ctx.DataLength()) {
+			return
+		}
+		// The last context need not be followed by padding.
+		need := ctx.aligned()
+		if need > uint(len(data)) {
+			need = uint(len(data))
+		}
+		if !fn(ctx) {
+			return
+		}
+		data = data[need:]
+	}
+}
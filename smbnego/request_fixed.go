@@ -0,0 +1,26 @@
+package smbnego
+
+//go:generate go run ../cmd/smbmarshalgen -out request_fixed_generated.go request_fixed.go
+
+// +smbmarshal structureSize=36
+//
+// RequestFixed describes the 36-byte fixed portion of a negotiate request,
+// as consumed by cmd/smbmarshalgen to generate its MarshalBytes,
+// UnmarshalBytes, and SizeBytes implementation in
+// request_fixed_generated.go.
+//
+// Field5 covers bytes 28 through 36, which the specification overlays with
+// either ClientStartTime or NegotiateContextOffset/NegotiateContextCount/
+// Reserved2 depending on the highest dialect offered; Request decodes it
+// according to the dialects actually present.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/69a29f73-de0c-45a6-a1aa-8ceeea42217f
+type RequestFixed struct {
+	StructureSize uint16
+	DialectCount  uint16
+	SecurityMode  uint16
+	Reserved      uint16
+	Capabilities  uint32
+	ClientID      [16]byte
+	Field5        [8]byte
+}
@@ -0,0 +1,123 @@
+package smbnego
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/gentlemanautomaton/smb/smbcap"
+	"github.com/gentlemanautomaton/smb/smbdialect"
+	"github.com/gentlemanautomaton/smb/smbid"
+	"github.com/gentlemanautomaton/smb/smbsecmode"
+)
+
+// requestHeaderLength is the length in bytes of the fixed portion of a
+// negotiate request, before the dialect array.
+const requestHeaderLength = 36
+
+// RequestBuilder assembles a Request from high-level inputs, computing the
+// dialect count and, for SMB 3.1.1, the 8-byte-aligned negotiate context
+// offset and count automatically.
+//
+// The zero value is an empty builder ready to use.
+type RequestBuilder struct {
+	securityMode    smbsecmode.Flags
+	clientID        smbid.ID
+	capabilities    smbcap.Flags
+	clientStartTime time.Time
+	dialects        []smbdialect.Revision
+	contexts        ContextListBuilder
+}
+
+// SetSecurityMode sets the security mode.
+func (b *RequestBuilder) SetSecurityMode(flags smbsecmode.Flags) *RequestBuilder {
+	b.securityMode = flags
+	return b
+}
+
+// SetClientID sets the client identifier.
+func (b *RequestBuilder) SetClientID(id smbid.ID) *RequestBuilder {
+	b.clientID = id
+	return b
+}
+
+// SetCapabilities sets the capability flags.
+func (b *RequestBuilder) SetCapabilities(flags smbcap.Flags) *RequestBuilder {
+	b.capabilities = flags
+	return b
+}
+
+// SetClientStartTime sets the client start time. It is ignored when the
+// highest offered dialect is SMB 3.1.1, where the same bytes instead carry
+// the negotiate context offset and count.
+func (b *RequestBuilder) SetClientStartTime(t time.Time) *RequestBuilder {
+	b.clientStartTime = t
+	return b
+}
+
+// AddDialect appends a dialect to the set offered by the request.
+func (b *RequestBuilder) AddDialect(revision smbdialect.Revision) *RequestBuilder {
+	b.dialects = append(b.dialects, revision)
+	return b
+}
+
+// AddContext appends a negotiate context to the request. Contexts are only
+// emitted when SMB 3.1.1 is among the offered dialects.
+func (b *RequestBuilder) AddContext(t ContextType, data []byte) *RequestBuilder {
+	b.contexts.Add(t, data)
+	return b
+}
+
+// offers311 returns true if SMB 3.1.1 is among the offered dialects.
+func (b *RequestBuilder) offers311() bool {
+	for _, d := range b.dialects {
+		if d == smbdialect.SMB311 {
+			return true
+		}
+	}
+	return false
+}
+
+// Build assembles the request, computing the dialect array and, for
+// SMB 3.1.1, the 8-byte-aligned negotiate context offset and count.
+func (b *RequestBuilder) Build() Request {
+	dialectsOffset := uint(requestHeaderLength)
+	dialectsLen := uint(len(b.dialects)) * 2
+	total := dialectsOffset + dialectsLen
+
+	var ctxOffset uint
+	ctxBytes := b.contexts.Bytes()
+	// Once SMB 3.1.1 is offered, bytes 28-36 of the request MUST be
+	// interpreted as NegotiateContextOffset/NegotiateContextCount/
+	// Reserved2 regardless of whether any contexts were actually added;
+	// ClientStartTime is not a valid alternate reading in that case.
+	includeContexts := b.offers311()
+	if includeContexts {
+		ctxOffset = align8(total)
+		total = ctxOffset + uint(len(ctxBytes))
+	}
+
+	buf := make([]byte, total)
+
+	r := Request(buf)
+	r.SetSize(36)
+	r.SetDialectCount(uint16(len(b.dialects)))
+	r.SetSecurityMode(b.securityMode)
+	r.SetCapabilities(b.capabilities)
+	r.SetClientID(b.clientID)
+	for i, d := range b.dialects {
+		offset := int(dialectsOffset) + i*2
+		binary.LittleEndian.PutUint16(buf[offset:offset+2], uint16(d))
+	}
+
+	if includeContexts {
+		copy(buf[ctxOffset:], ctxBytes)
+		// ContextOffset is specified relative to the start of the SMB2
+		// packet header, which precedes r by PacketHeaderLength bytes.
+		r.SetContextOffset(uint32(ctxOffset + PacketHeaderLength))
+		r.SetContextCount(b.contexts.Count())
+	} else {
+		r.SetClientStartTime(b.clientStartTime)
+	}
+
+	return r
+}
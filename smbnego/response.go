@@ -1,7 +1,6 @@
 package smbnego
 
 import (
-	"encoding/binary"
 	"time"
 
 	"github.com/gentlemanautomaton/smb/smbcap"
@@ -13,31 +12,60 @@ import (
 
 // Response interprets a slice of bytes as an SMB negotiation response packet.
 //
+// The fixed-size fields of the response are described by ResponseFixed; the
+// accessors below are a thin compatibility layer over its generated
+// MarshalBytes/UnmarshalBytes methods, retained so that callers can keep
+// treating a response as a plain byte slice.
+//
 // https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/63abf97c-0d09-47e2-88d6-6bfa552949a5
 type Response []byte
 
+// fixed unmarshals the fixed-size portion of the response. The slice is
+// always exactly responseHeaderLength bytes, so the error return of
+// UnmarshalBytes can never trigger here.
+func (r Response) fixed() (f ResponseFixed) {
+	_ = f.UnmarshalBytes(r[:responseHeaderLength])
+	return
+}
+
+// putFixed marshals f back into the fixed-size portion of the response.
+func (r Response) putFixed(f ResponseFixed) {
+	f.MarshalBytes(r[:responseHeaderLength])
+}
+
 // Valid returns true if the response is valid.
 func (r Response) Valid() bool {
-	if len(r) < 64 {
+	if len(r) < responseHeaderLength {
 		return false
 	}
 
+	f := r.fixed()
+
 	// The spec requires the size field to be 65
-	if r.Size() != 65 {
+	if !f.Valid() {
 		return false
 	}
 
-	// The security buffer must not overflow
-	if int(r.SecurityBufferOffset())+int(r.SecurityBufferLength()) > len(r) {
+	// SecurityBufferOffset is relative to the start of the SMB2 packet
+	// header, which precedes r; translate it to a body-relative offset
+	// before checking that the security buffer fits within r.
+	secOffset := int(r.SecurityBufferOffset()) - PacketHeaderLength
+	if secOffset < 0 || secOffset+int(r.SecurityBufferLength()) > len(r) {
 		return false
 	}
 
 	// In SMB 3.1.1 the negotiation contexts must not overflow
 	if r.DialectRevision() == smbdialect.SMB311 {
+		// ContextOffset is likewise relative to the SMB2 packet header.
+		ctxOffset := int64(r.ContextOffset()) - PacketHeaderLength
+		if ctxOffset < 0 {
+			return false
+		}
+
 		// Make sure the context count is compatible with the size of the
 		// response. The size of each context is variable but at least 8 bytes.
-		minimumLength := uint(r.ContextOffset()) + uint(r.ContextCount())*ContextHeaderLength
-		if minimumLength > uint(len(r)) {
+		minimumLength := uint64(ctxOffset) + uint64(r.ContextCount())*ContextHeaderLength
+		if minimumLength > uint64(len(r)) {
 			return false
 		}
 
@@ -50,169 +78,200 @@ func (r Response) Valid() bool {
 	return true
 }
 
-// Size returns the structure size of the response. The specification requires
-// that this be 65, regardless of the size of the security buffer or the
-// number of negotiation contexts.
+// Size returns the structure size of the response. The specification
+// requires that this be 65, regardless of the size of the security buffer
+// or the number of negotiation contexts.
 func (r Response) Size() uint16 {
-	return binary.LittleEndian.Uint16(r[0:2])
+	return r.fixed().StructureSize
 }
 
 // SetSize sets the structure size of the response.
 func (r Response) SetSize(size uint16) {
-	binary.LittleEndian.PutUint16(r[0:2], size)
+	f := r.fixed()
+	f.StructureSize = size
+	r.putFixed(f)
 }
 
 // SecurityMode returns the security mode of the response.
 func (r Response) SecurityMode() smbsecmode.Flags {
-	return smbsecmode.Flags(binary.LittleEndian.Uint16(r[2:4]))
+	return smbsecmode.Flags(r.fixed().SecurityMode)
 }
 
 // SetSecurityMode sets the security mode of the response.
 func (r Response) SetSecurityMode(flags smbsecmode.Flags) {
-	binary.LittleEndian.PutUint16(r[2:4], uint16(flags))
+	f := r.fixed()
+	f.SecurityMode = uint16(flags)
+	r.putFixed(f)
 }
 
 // DialectRevision returns the dialect revision of the response.
 func (r Response) DialectRevision() smbdialect.Revision {
-	return smbdialect.Revision(binary.LittleEndian.Uint16(r[4:6]))
+	return smbdialect.Revision(r.fixed().DialectRevision)
 }
 
 // SetDialectRevision sets the dialect revision of the response.
 func (r Response) SetDialectRevision(revision smbdialect.Revision) {
-	binary.LittleEndian.PutUint16(r[4:6], uint16(revision))
+	f := r.fixed()
+	f.DialectRevision = uint16(revision)
+	r.putFixed(f)
 }
 
 // ContextCount returns the context count of the response.
 //
 // This field is only valid in the SMB 3.1.1 dialect.
 func (r Response) ContextCount() uint16 {
-	return binary.LittleEndian.Uint16(r[6:8])
+	return r.fixed().ContextCount
 }
 
 // SetContextCount sets the context count of the response.
 //
 // This field is only valid in the SMB 3.1.1 dialect.
 func (r Response) SetContextCount(size uint16) {
-	binary.LittleEndian.PutUint16(r[6:8], size)
+	f := r.fixed()
+	f.ContextCount = size
+	r.putFixed(f)
 }
 
 // ServerID returns the server identifier of the response.
 func (r Response) ServerID() (id smbid.ID) {
-	id.Read(r[8:24])
+	f := r.fixed()
+	id.Read(f.ServerID[:])
 	return
 }
 
 // SetServerID sets the server identifier of the response.
 func (r Response) SetServerID(id smbid.ID) {
-	id.Write(r[8:24])
+	f := r.fixed()
+	id.Write(f.ServerID[:])
+	r.putFixed(f)
 }
 
 // Capabilities returns the capability flags of the response.
 func (r Response) Capabilities() smbcap.Flags {
-	return smbcap.Flags(binary.LittleEndian.Uint32(r[24:28]))
+	return smbcap.Flags(r.fixed().Capabilities)
 }
 
 // SetCapabilities sets the capability flags of the response.
 func (r Response) SetCapabilities(flags smbcap.Flags) {
-	binary.LittleEndian.PutUint32(r[24:28], uint32(flags))
+	f := r.fixed()
+	f.Capabilities = uint32(flags)
+	r.putFixed(f)
 }
 
 // MaxTransactSize returns the maximum transaction size of the response.
 func (r Response) MaxTransactSize() uint32 {
-	return binary.LittleEndian.Uint32(r[28:32])
+	return r.fixed().MaxTransactSize
 }
 
 // SetMaxTransactSize sets the maximum transaction size of the response.
-func (r Response) SetMaxTransactSize(flags uint32) {
-	binary.LittleEndian.PutUint32(r[28:32], flags)
+func (r Response) SetMaxTransactSize(size uint32) {
+	f := r.fixed()
+	f.MaxTransactSize = size
+	r.putFixed(f)
 }
 
 // MaxReadSize returns the maximum read size of the response.
 func (r Response) MaxReadSize() uint32 {
-	return binary.LittleEndian.Uint32(r[32:36])
+	return r.fixed().MaxReadSize
 }
 
 // SetMaxReadSize sets the maximum read size of the response.
-func (r Response) SetMaxReadSize(flags uint32) {
-	binary.LittleEndian.PutUint32(r[32:36], flags)
+func (r Response) SetMaxReadSize(size uint32) {
+	f := r.fixed()
+	f.MaxReadSize = size
+	r.putFixed(f)
 }
 
 // MaxWriteSize returns the maximum write size of the response.
 func (r Response) MaxWriteSize() uint32 {
-	return binary.LittleEndian.Uint32(r[36:40])
+	return r.fixed().MaxWriteSize
 }
 
 // SetMaxWriteSize sets the maximum write size of the response.
-func (r Response) SetMaxWriteSize(flags uint32) {
-	binary.LittleEndian.PutUint32(r[36:40], flags)
+func (r Response) SetMaxWriteSize(size uint32) {
+	f := r.fixed()
+	f.MaxWriteSize = size
+	r.putFixed(f)
 }
 
 // SystemTime returns the system time of the response.
 func (r Response) SystemTime() time.Time {
-	return smbtype.Time(r[40:48])
+	f := r.fixed()
+	return smbtype.Time(f.SystemTime[:])
 }
 
 // SetSystemTime sets the system time of the response.
 func (r Response) SetSystemTime(t time.Time) {
-	smbtype.PutTime(r[40:48], t)
+	f := r.fixed()
+	smbtype.PutTime(f.SystemTime[:], t)
+	r.putFixed(f)
 }
 
 // ServerStartTime returns the server start time of the response.
 func (r Response) ServerStartTime() time.Time {
-	return smbtype.Time(r[48:56])
+	f := r.fixed()
+	return smbtype.Time(f.ServerStartTime[:])
 }
 
 // SetServerStartTime sets the server start time of the response.
 func (r Response) SetServerStartTime(t time.Time) {
-	smbtype.PutTime(r[48:56], t)
+	f := r.fixed()
+	smbtype.PutTime(f.ServerStartTime[:], t)
+	r.putFixed(f)
 }
 
-// SecurityBufferOffset returns the offset of the security buffer within the
-// response.
+// SecurityBufferOffset returns the offset of the security buffer, measured
+// from the start of the SMB2 packet header that precedes the response.
 func (r Response) SecurityBufferOffset() uint16 {
-	return binary.LittleEndian.Uint16(r[56:58])
+	return r.fixed().SecurityBufferOffset
 }
 
-// SetSecurityBufferOffset sets the offset of the security buffer within the
-// response.
+// SetSecurityBufferOffset sets the offset of the security buffer, measured
+// from the start of the SMB2 packet header that precedes the response.
 func (r Response) SetSecurityBufferOffset(offset uint16) {
-	binary.LittleEndian.PutUint16(r[56:58], offset)
+	f := r.fixed()
+	f.SecurityBufferOffset = offset
+	r.putFixed(f)
 }
 
 // SecurityBufferLength returns the length of the security buffer within the
 // response.
 func (r Response) SecurityBufferLength() uint16 {
-	return binary.LittleEndian.Uint16(r[58:60])
+	return r.fixed().SecurityBufferLength
 }
 
 // SetSecurityBufferLength sets the length of the security buffer within the
 // response.
 func (r Response) SetSecurityBufferLength(length uint16) {
-	binary.LittleEndian.PutUint16(r[58:60], length)
+	f := r.fixed()
+	f.SecurityBufferLength = length
+	r.putFixed(f)
 }
 
 // SecurityBuffer returns the bytes of the security buffer from the response.
 func (r Response) SecurityBuffer() []byte {
-	start := uint(r.SecurityBufferOffset())
+	start := uint(r.SecurityBufferOffset()) - PacketHeaderLength
 	length := uint(r.SecurityBufferLength())
 	end := start + length
 	return r[start:end:end]
 }
 
-// ContextOffset returns the offset of the first negotiate context
-// within the response.
+// ContextOffset returns the offset of the first negotiate context, measured
+// from the start of the SMB2 packet header that precedes the response.
 //
 // This field is only valid in the SMB 3.1.1 dialect.
 func (r Response) ContextOffset() uint32 {
-	return binary.LittleEndian.Uint32(r[60:64])
+	return r.fixed().ContextOffset
 }
 
-// SetContextOffset sets the offset of the first negotiate context
-// within the response.
+// SetContextOffset sets the offset of the first negotiate context, measured
+// from the start of the SMB2 packet header that precedes the response.
 //
 // This field is only valid in the SMB 3.1.1 dialect.
 func (r Response) SetContextOffset(size uint32) {
-	binary.LittleEndian.PutUint32(r[60:64], size)
+	f := r.fixed()
+	f.ContextOffset = size
+	r.putFixed(f)
 }
 
 // ContextList returns the negotiation context list from the response.
@@ -221,5 +280,5 @@ func (r Response) SetContextOffset(size uint32) {
 //
 // This field is only valid in the SMB 3.1.1 dialect.
 func (r Response) ContextList() ContextList {
-	return ContextList(r[r.ContextOffset():])
+	return ContextList(r[uint(r.ContextOffset())-PacketHeaderLength:])
 }
@@ -0,0 +1,28 @@
+package smbnego
+
+//go:generate go run ../cmd/smbmarshalgen -out response_fixed_generated.go response_fixed.go
+
+// +smbmarshal structureSize=65
+//
+// ResponseFixed describes the 64-byte fixed portion of a negotiate
+// response, as consumed by cmd/smbmarshalgen to generate its
+// MarshalBytes, UnmarshalBytes, and SizeBytes implementation in
+// response_fixed_generated.go.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/63abf97c-0d09-47e2-88d6-6bfa552949a5
+type ResponseFixed struct {
+	StructureSize        uint16
+	SecurityMode         uint16
+	DialectRevision      uint16
+	ContextCount         uint16
+	ServerID             [16]byte
+	Capabilities         uint32
+	MaxTransactSize      uint32
+	MaxReadSize          uint32
+	MaxWriteSize         uint32
+	SystemTime           [8]byte
+	ServerStartTime      [8]byte
+	SecurityBufferOffset uint16
+	SecurityBufferLength uint16
+	ContextOffset        uint32
+}
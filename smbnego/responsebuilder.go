@@ -0,0 +1,147 @@
+package smbnego
+
+import (
+	"time"
+
+	"github.com/gentlemanautomaton/smb/smbcap"
+	"github.com/gentlemanautomaton/smb/smbdialect"
+	"github.com/gentlemanautomaton/smb/smbid"
+	"github.com/gentlemanautomaton/smb/smbsecmode"
+)
+
+// responseHeaderLength is the length in bytes of the fixed portion of a
+// negotiate response, before the security buffer.
+const responseHeaderLength = 64
+
+// ResponseBuilder assembles a Response from high-level inputs, computing
+// the security buffer offset and negotiate context offset, length, and
+// alignment automatically.
+//
+// The zero value is an empty builder ready to use.
+type ResponseBuilder struct {
+	dialect         smbdialect.Revision
+	securityMode    smbsecmode.Flags
+	serverID        smbid.ID
+	capabilities    smbcap.Flags
+	maxTransactSize uint32
+	maxReadSize     uint32
+	maxWriteSize    uint32
+	systemTime      time.Time
+	serverStartTime time.Time
+	securityBuffer  []byte
+	contexts        ContextListBuilder
+}
+
+// SetDialectRevision sets the negotiated dialect revision.
+func (b *ResponseBuilder) SetDialectRevision(revision smbdialect.Revision) *ResponseBuilder {
+	b.dialect = revision
+	return b
+}
+
+// SetSecurityMode sets the security mode.
+func (b *ResponseBuilder) SetSecurityMode(flags smbsecmode.Flags) *ResponseBuilder {
+	b.securityMode = flags
+	return b
+}
+
+// SetServerID sets the server identifier.
+func (b *ResponseBuilder) SetServerID(id smbid.ID) *ResponseBuilder {
+	b.serverID = id
+	return b
+}
+
+// SetCapabilities sets the capability flags.
+func (b *ResponseBuilder) SetCapabilities(flags smbcap.Flags) *ResponseBuilder {
+	b.capabilities = flags
+	return b
+}
+
+// SetMaxTransactSize sets the maximum transaction size.
+func (b *ResponseBuilder) SetMaxTransactSize(size uint32) *ResponseBuilder {
+	b.maxTransactSize = size
+	return b
+}
+
+// SetMaxReadSize sets the maximum read size.
+func (b *ResponseBuilder) SetMaxReadSize(size uint32) *ResponseBuilder {
+	b.maxReadSize = size
+	return b
+}
+
+// SetMaxWriteSize sets the maximum write size.
+func (b *ResponseBuilder) SetMaxWriteSize(size uint32) *ResponseBuilder {
+	b.maxWriteSize = size
+	return b
+}
+
+// SetSystemTime sets the system time.
+func (b *ResponseBuilder) SetSystemTime(t time.Time) *ResponseBuilder {
+	b.systemTime = t
+	return b
+}
+
+// SetServerStartTime sets the server start time.
+func (b *ResponseBuilder) SetServerStartTime(t time.Time) *ResponseBuilder {
+	b.serverStartTime = t
+	return b
+}
+
+// SetSecurityBuffer sets the raw security token to be carried in the
+// response's security buffer.
+func (b *ResponseBuilder) SetSecurityBuffer(token []byte) *ResponseBuilder {
+	b.securityBuffer = token
+	return b
+}
+
+// AddContext appends a negotiate context to the response. Contexts are only
+// emitted when the dialect revision is SMB 3.1.1.
+func (b *ResponseBuilder) AddContext(t ContextType, data []byte) *ResponseBuilder {
+	b.contexts.Add(t, data)
+	return b
+}
+
+// Build assembles the response, computing the security buffer offset and
+// length and, for SMB 3.1.1, the 8-byte-aligned negotiate context offset
+// and count.
+func (b *ResponseBuilder) Build() Response {
+	secOffset := uint(responseHeaderLength)
+	secLen := uint(len(b.securityBuffer))
+	total := secOffset + secLen
+
+	var ctxOffset uint
+	ctxBytes := b.contexts.Bytes()
+	includeContexts := b.dialect == smbdialect.SMB311 && b.contexts.Count() > 0
+	if includeContexts {
+		ctxOffset = align8(total)
+		total = ctxOffset + uint(len(ctxBytes))
+	}
+
+	buf := make([]byte, total)
+	copy(buf[secOffset:], b.securityBuffer)
+	if includeContexts {
+		copy(buf[ctxOffset:], ctxBytes)
+	}
+
+	r := Response(buf)
+	r.SetSize(65)
+	r.SetSecurityMode(b.securityMode)
+	r.SetDialectRevision(b.dialect)
+	r.SetServerID(b.serverID)
+	r.SetCapabilities(b.capabilities)
+	r.SetMaxTransactSize(b.maxTransactSize)
+	r.SetMaxReadSize(b.maxReadSize)
+	r.SetMaxWriteSize(b.maxWriteSize)
+	r.SetSystemTime(b.systemTime)
+	r.SetServerStartTime(b.serverStartTime)
+	// SecurityBufferOffset and ContextOffset are specified relative to the
+	// start of the SMB2 packet header, which precedes r by
+	// PacketHeaderLength bytes.
+	r.SetSecurityBufferOffset(uint16(secOffset + PacketHeaderLength))
+	r.SetSecurityBufferLength(uint16(secLen))
+	if includeContexts {
+		r.SetContextOffset(uint32(ctxOffset + PacketHeaderLength))
+		r.SetContextCount(b.contexts.Count())
+	}
+
+	return r
+}
@@ -0,0 +1,50 @@
+package smbscan
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// smb2HeaderLength is the length in bytes of a synchronous SMB2 packet
+// header.
+const smb2HeaderLength = 64
+
+// smb2ProtocolID identifies an SMB2/SMB3 packet, as opposed to the 0xFF
+// 'S' 'M' 'B' ID used by the legacy SMB1 dialect.
+var smb2ProtocolID = [4]byte{0xFE, 'S', 'M', 'B'}
+
+// cmdNegotiate is the SMB2 NEGOTIATE command code.
+const cmdNegotiate uint16 = 0x0000
+
+// buildNegotiatePacket wraps body, a marshaled smbnego.Request, in an SMB2
+// packet header addressed to the NEGOTIATE command.
+func buildNegotiatePacket(body []byte) []byte {
+	packet := make([]byte, smb2HeaderLength+len(body))
+
+	copy(packet[0:4], smb2ProtocolID[:])
+	binary.LittleEndian.PutUint16(packet[4:6], smb2HeaderLength)
+	binary.LittleEndian.PutUint16(packet[12:14], cmdNegotiate)
+	binary.LittleEndian.PutUint16(packet[14:16], 1) // CreditRequest
+
+	copy(packet[smb2HeaderLength:], body)
+	return packet
+}
+
+// negotiateResponseBody strips the SMB2 packet header from packet and
+// returns the NEGOTIATE response body, verifying the protocol ID and
+// command code along the way.
+func negotiateResponseBody(packet []byte) ([]byte, error) {
+	if len(packet) < smb2HeaderLength {
+		return nil, fmt.Errorf("smbscan: packet too short for an SMB2 header (%d bytes)", len(packet))
+	}
+	if string(packet[0:4]) != string(smb2ProtocolID[:]) {
+		return nil, fmt.Errorf("smbscan: not an SMB2 packet")
+	}
+	if cmd := binary.LittleEndian.Uint16(packet[12:14]); cmd != cmdNegotiate {
+		return nil, fmt.Errorf("smbscan: expected NEGOTIATE response, got command 0x%04x", cmd)
+	}
+	if status := binary.LittleEndian.Uint32(packet[8:12]); status != 0 {
+		return nil, fmt.Errorf("smbscan: server returned NT status 0x%08x", status)
+	}
+	return packet[smb2HeaderLength:], nil
+}
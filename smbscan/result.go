@@ -0,0 +1,29 @@
+package smbscan
+
+import "time"
+
+// Result is a structured record of the negotiate response obtained from a
+// single host.
+type Result struct {
+	Address string `json:"address"`
+
+	Dialect      string `json:"dialect"`
+	ServerGUID   string `json:"serverGUID"`
+	SecurityMode uint16 `json:"securityMode"`
+	Capabilities uint32 `json:"capabilities"`
+
+	MaxTransactSize uint32 `json:"maxTransactSize"`
+	MaxReadSize     uint32 `json:"maxReadSize"`
+	MaxWriteSize    uint32 `json:"maxWriteSize"`
+
+	SystemTime      time.Time `json:"systemTime"`
+	ServerStartTime time.Time `json:"serverStartTime"`
+
+	Cipher                string   `json:"cipher,omitempty"`
+	HashAlgorithm         string   `json:"hashAlgorithm,omitempty"`
+	CompressionAlgorithms []string `json:"compressionAlgorithms,omitempty"`
+
+	SecurityBlob []byte `json:"securityBlob"`
+
+	Error string `json:"error,omitempty"`
+}
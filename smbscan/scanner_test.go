@@ -0,0 +1,63 @@
+package smbscan
+
+import "testing"
+
+// TestStreamHostsInCIDR confirms that streamHostsInCIDR enumerates the
+// usable host addresses of a range, omitting the network and broadcast
+// addresses for ranges larger than /31.
+func TestStreamHostsInCIDR(t *testing.T) {
+	addrs := make(chan string, 16)
+	if err := streamHostsInCIDR("192.0.2.0/29", addrs); err != nil {
+		t.Fatalf("streamHostsInCIDR: %v", err)
+	}
+	close(addrs)
+
+	want := []string{
+		"192.0.2.1",
+		"192.0.2.2",
+		"192.0.2.3",
+		"192.0.2.4",
+		"192.0.2.5",
+		"192.0.2.6",
+	}
+
+	var got []string
+	for addr := range addrs {
+		got = append(got, addr)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("unexpected address count: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected address at index %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+// TestStreamHostsInCIDRPointToPoint confirms that a /31 (which MS-SMB2
+// deployments sometimes use for point-to-point links) yields both
+// addresses, since there is no network or broadcast address to omit.
+func TestStreamHostsInCIDRPointToPoint(t *testing.T) {
+	addrs := make(chan string, 2)
+	if err := streamHostsInCIDR("192.0.2.0/31", addrs); err != nil {
+		t.Fatalf("streamHostsInCIDR: %v", err)
+	}
+	close(addrs)
+
+	var got []string
+	for addr := range addrs {
+		got = append(got, addr)
+	}
+
+	want := []string{"192.0.2.0", "192.0.2.1"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected address count: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected address at index %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package smbscan
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// maxMessageLength bounds the size of a single direct TCP transport message
+// (MS-SMB2 §2.1), guarding against a malicious or malfunctioning server
+// advertising an unreasonable length.
+const maxMessageLength = 16 * 1024 * 1024
+
+// writeMessage frames msg as a direct TCP transport packet (a 4-byte
+// big-endian length prefix, the high byte of which is reserved and always
+// zero) and writes it to conn.
+//
+// https://docs.microsoft.com/en-us/openspecs/windows_protocols/ms-smb2/3cf8b6eb-2e15-4a45-9c35-6e8e6dbb7f93
+func writeMessage(conn net.Conn, msg []byte) error {
+	prefix := make([]byte, 4)
+	binary.BigEndian.PutUint32(prefix, uint32(len(msg)))
+	if _, err := conn.Write(prefix); err != nil {
+		return err
+	}
+	_, err := conn.Write(msg)
+	return err
+}
+
+// readMessage reads a single direct TCP transport packet from conn.
+func readMessage(conn net.Conn) ([]byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(conn, prefix[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(prefix[:])
+	if length > maxMessageLength {
+		return nil, fmt.Errorf("smbscan: message length %d exceeds maximum of %d", length, maxMessageLength)
+	}
+
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,34 @@
+package smbscan
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+// TestWriteReadMessageRoundTrip confirms that readMessage recovers exactly
+// the bytes framed by writeMessage across an in-memory connection.
+func TestWriteReadMessageRoundTrip(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	msg := []byte("a negotiate request body")
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- writeMessage(client, msg)
+	}()
+
+	got, err := readMessage(server)
+	if err != nil {
+		t.Fatalf("readMessage: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("writeMessage: %v", err)
+	}
+
+	if !bytes.Equal(got, msg) {
+		t.Fatalf("round trip mismatch: got %q, want %q", got, msg)
+	}
+}